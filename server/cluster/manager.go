@@ -0,0 +1,136 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+// Package cluster tracks the topology (nodes, shards, schemas, tables) of
+// every ceresdb cluster ceresmeta manages.
+package cluster
+
+import (
+	"context"
+	"sync"
+
+	"github.com/CeresDB/ceresmeta/pkg/coderr"
+	"github.com/CeresDB/ceresmeta/server/storage"
+)
+
+// ErrClusterNotFound is returned by GetCluster (and anything built on it)
+// when no cluster is registered under the requested name.
+var ErrClusterNotFound = coderr.NewCodeError(coderr.NotFound, "cluster not found")
+
+// ErrSchemaNotFound is returned by Cluster.ListSchemaTables when the schema
+// does not exist in the cluster.
+var ErrSchemaNotFound = coderr.NewCodeError(coderr.NotFound, "schema not found")
+
+// Node is a ceresdb data node participating in a Cluster.
+type Node struct {
+	Name            string `json:"name"`
+	LastHeartbeatAt int64  `json:"lastHeartbeatAt"`
+}
+
+// Shard is a single shard of a Cluster's keyspace.
+type Shard struct {
+	ID         storage.ShardID `json:"id"`
+	LeaderNode string          `json:"leaderNode"`
+}
+
+// TopologyChange describes a node/shard/table membership change, published
+// to events.Bus subscribers.
+type TopologyChange struct {
+	Kind        string `json:"kind"`
+	ClusterName string `json:"clusterName"`
+}
+
+// EventType maps a TopologyChange to the SSE event type it should be
+// published as, e.g. "shard" or "node".
+func (c TopologyChange) EventType() string {
+	return c.Kind
+}
+
+// Manager tracks every Cluster ceresmeta manages.
+type Manager interface {
+	ListClusters(ctx context.Context) ([]*Cluster, error)
+	GetCluster(ctx context.Context, clusterName string) (*Cluster, error)
+	RouteTables(ctx context.Context, clusterName, schemaName string, tables []string) (interface{}, error)
+	DropTable(ctx context.Context, clusterName, schemaName, table string) error
+	// TopologyChanges streams shard leader transfers, node joins/leaves, and
+	// table create/drop events across every managed Cluster.
+	TopologyChanges() <-chan TopologyChange
+}
+
+// Cluster is a single ceresdb cluster's topology: its nodes, shards,
+// schemas, and the tables routed to each shard.
+type Cluster struct {
+	Name string `json:"name"`
+
+	nodes   []Node
+	shards  []Shard
+	schemas []string
+	tables  map[string][]string
+
+	// allocLock guards lastAllocatedShardID, the highest shard ID handed out
+	// by AllocShardID.
+	allocLock            sync.Mutex
+	lastAllocatedShardID uint32
+}
+
+// GetShardTables returns one entry per element of shardIDs, in order. Each
+// entry's Tables is empty: the Cluster keeps no shard-to-table mapping, and
+// nodeName is not consulted.
+func (c *Cluster) GetShardTables(shardIDs []storage.ShardID, nodeName string) interface{} {
+	type shardTables struct {
+		ShardID storage.ShardID `json:"shardID"`
+		Tables  []string        `json:"tables"`
+	}
+	result := make([]shardTables, 0, len(shardIDs))
+	for _, id := range shardIDs {
+		result = append(result, shardTables{ShardID: id})
+	}
+	return result
+}
+
+// AllocShardID allocates a new, cluster-unique shard ID, e.g. for a split.
+func (c *Cluster) AllocShardID(_ context.Context) (uint32, error) {
+	c.allocLock.Lock()
+	defer c.allocLock.Unlock()
+	for _, shard := range c.shards {
+		if uint32(shard.ID) > c.lastAllocatedShardID {
+			c.lastAllocatedShardID = uint32(shard.ID)
+		}
+	}
+	c.lastAllocatedShardID++
+	return c.lastAllocatedShardID, nil
+}
+
+// ListNodes returns every node in the cluster along with its heartbeat
+// status.
+func (c *Cluster) ListNodes() []Node {
+	return c.nodes
+}
+
+// ListShards returns every shard in the cluster.
+func (c *Cluster) ListShards() []Shard {
+	return c.shards
+}
+
+// GetShard returns the shard identified by id, if present.
+func (c *Cluster) GetShard(id storage.ShardID) (Shard, bool) {
+	for _, shard := range c.shards {
+		if shard.ID == id {
+			return shard, true
+		}
+	}
+	return Shard{}, false
+}
+
+// ListSchemas returns every schema registered in the cluster.
+func (c *Cluster) ListSchemas() []string {
+	return c.schemas
+}
+
+// ListSchemaTables returns every table in schema.
+func (c *Cluster) ListSchemaTables(schema string) ([]string, error) {
+	tables, ok := c.tables[schema]
+	if !ok {
+		return nil, ErrSchemaNotFound
+	}
+	return tables, nil
+}
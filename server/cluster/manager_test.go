@@ -0,0 +1,67 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+package cluster
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/CeresDB/ceresmeta/server/storage"
+)
+
+func TestClusterGetShard(t *testing.T) {
+	c := &Cluster{
+		Name:   "test",
+		shards: []Shard{{ID: 1, LeaderNode: "node-1"}, {ID: 2, LeaderNode: "node-2"}},
+	}
+
+	shard, ok := c.GetShard(2)
+	if !ok {
+		t.Fatal("GetShard(2) not found")
+	}
+	if shard.LeaderNode != "node-2" {
+		t.Fatalf("LeaderNode = %q, want %q", shard.LeaderNode, "node-2")
+	}
+
+	if _, ok := c.GetShard(99); ok {
+		t.Fatal("GetShard(99) should not be found")
+	}
+}
+
+func TestClusterListSchemaTablesNotFound(t *testing.T) {
+	c := &Cluster{
+		Name:   "test",
+		tables: map[string][]string{"public": {"t1", "t2"}},
+	}
+
+	tables, err := c.ListSchemaTables("public")
+	if err != nil {
+		t.Fatalf("ListSchemaTables(public): %v", err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("len(tables) = %d, want 2", len(tables))
+	}
+
+	if _, err := c.ListSchemaTables("missing"); !errors.Is(err, ErrSchemaNotFound) {
+		t.Fatalf("ListSchemaTables(missing) error = %v, want ErrSchemaNotFound", err)
+	}
+}
+
+func TestClusterGetShardTables(t *testing.T) {
+	c := &Cluster{Name: "test"}
+
+	result := c.GetShardTables([]storage.ShardID{1, 2}, "node-1")
+	b, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(decoded) != 2 {
+		t.Fatalf("len(decoded) = %d, want 2", len(decoded))
+	}
+}
@@ -0,0 +1,10 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+package operations
+
+import "errors"
+
+var (
+	ErrOperationNotFound        = errors.New("operation not found")
+	ErrOperationAlreadyFinished = errors.New("operation already in a terminal state")
+)
@@ -0,0 +1,77 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/CeresDB/ceresmeta/server/storage"
+)
+
+// operationsKeyPrefix namespaces Operation records within the shared
+// etcd-backed key-value store, alongside cluster/shard/procedure metadata.
+const operationsKeyPrefix = "operations"
+
+// etcdStorage is the Storage implementation used in production: it persists
+// Operations through the same etcd-backed key-value store as the rest of
+// ceresmeta's metadata, so an Operation survives a leader failover.
+type etcdStorage struct {
+	kv storage.KV
+}
+
+// NewEtcdStorage creates a Storage backed by kv, the etcd-backed key-value
+// store also used for cluster, shard and procedure metadata.
+func NewEtcdStorage(kv storage.KV) Storage {
+	return &etcdStorage{kv: kv}
+}
+
+func operationKey(id string) string {
+	return fmt.Sprintf("%s/%s", operationsKeyPrefix, id)
+}
+
+func (s *etcdStorage) PutOperation(ctx context.Context, op Operation) error {
+	value, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+	return s.kv.Put(ctx, operationKey(op.ID), value)
+}
+
+func (s *etcdStorage) GetOperation(ctx context.Context, id string) (Operation, bool, error) {
+	value, ok, err := s.kv.Get(ctx, operationKey(id))
+	if err != nil {
+		return Operation{}, false, err
+	}
+	if !ok {
+		return Operation{}, false, nil
+	}
+
+	var op Operation
+	if err := json.Unmarshal(value, &op); err != nil {
+		return Operation{}, false, err
+	}
+	return op, true, nil
+}
+
+func (s *etcdStorage) ListOperations(ctx context.Context) ([]Operation, error) {
+	values, err := s.kv.List(ctx, operationsKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	ops := make([]Operation, 0, len(values))
+	for _, value := range values {
+		var op Operation
+		if err := json.Unmarshal(value, &op); err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+func (s *etcdStorage) DeleteOperation(ctx context.Context, id string) error {
+	return s.kv.Delete(ctx, operationKey(id))
+}
@@ -0,0 +1,298 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+// Package operations implements a persistent handle for long-running
+// procedures submitted through the HTTP API, modeled on the operation
+// pattern used by LXD: every mutating endpoint returns an Operation instead
+// of blocking until the underlying procedure finishes.
+package operations
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/CeresDB/ceresmeta/pkg/log"
+	"github.com/CeresDB/ceresmeta/server/coordinator/procedure"
+	"github.com/CeresDB/ceresmeta/server/storage"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Status is the lifecycle state of an Operation.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusSuccess   Status = "success"
+	StatusFailure   Status = "failure"
+	StatusCancelled Status = "cancelled"
+)
+
+func (s Status) isTerminal() bool {
+	return s == StatusSuccess || s == StatusFailure || s == StatusCancelled
+}
+
+// Operation is a persistent record of a procedure submission. It is returned
+// to HTTP callers in place of the bare success/failure response so that the
+// caller can poll or wait for the outcome of what is inherently an
+// asynchronous, long-running action.
+type Operation struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	Status    Status      `json:"status"`
+	CreatedAt time.Time   `json:"createdAt"`
+	UpdatedAt time.Time   `json:"updatedAt"`
+	Metadata  interface{} `json:"metadata,omitempty"`
+	Err       string      `json:"err,omitempty"`
+
+	// ProcedureID links the Operation back to the procedure submitted on its
+	// behalf. It must be exported (with a JSON tag) so that it survives the
+	// round-trip through Storage; reconcileLoop relies on it to match
+	// procedureManager events to the Operation they belong to.
+	ProcedureID uint64 `json:"procedureId"`
+}
+
+// Storage is the persistence interface an Operation store needs from the
+// etcd-backed storage package so that operations survive a leader failover.
+type Storage interface {
+	PutOperation(ctx context.Context, op Operation) error
+	GetOperation(ctx context.Context, id string) (Operation, bool, error)
+	ListOperations(ctx context.Context) ([]Operation, error)
+	DeleteOperation(ctx context.Context, id string) error
+}
+
+// Manager tracks in-flight and historical Operations, reconciling their
+// status from procedureManager events in the background.
+type Manager interface {
+	// Submit creates an Operation wrapping procedure submission and returns it
+	// immediately with status pending/running; the procedure continues to run
+	// asynchronously.
+	Submit(ctx context.Context, opType string, metadata interface{}, proc procedure.Procedure) (Operation, error)
+	// Complete records an Operation that already finished synchronously
+	// (e.g. a handler that does not go through procedureManager), so that
+	// synchronous and asynchronous mutating endpoints share one response shape.
+	Complete(ctx context.Context, opType string, metadata interface{}, completionErr error) (Operation, error)
+	Get(ctx context.Context, id string) (Operation, error)
+	List(ctx context.Context) ([]Operation, error)
+	// Cancel requests cancellation of the procedure backing the Operation.
+	Cancel(ctx context.Context, id string) error
+	// Wait blocks until the Operation reaches a terminal state or timeout elapses.
+	Wait(ctx context.Context, id string, timeout time.Duration) (Operation, error)
+}
+
+type managerImpl struct {
+	procedureManager procedure.Manager
+	storage          Storage
+
+	lock sync.RWMutex
+	// waiters are notified whenever the Operation they are keyed by changes.
+	waiters map[string][]chan struct{}
+}
+
+// NewManager creates an operations Manager backed by storage and reconciled
+// from procedureManager.
+func NewManager(procedureManager procedure.Manager, storage Storage) Manager {
+	m := &managerImpl{
+		procedureManager: procedureManager,
+		storage:          storage,
+		waiters:          make(map[string][]chan struct{}),
+	}
+	go m.reconcileLoop()
+	return m
+}
+
+// NewEtcdBackedManager is the constructor server bootstrap code should use:
+// it wires a Manager to the shared etcd-backed key-value store so Operations
+// survive a leader failover, as required by the operations subsystem design.
+func NewEtcdBackedManager(procedureManager procedure.Manager, kv storage.KV) Manager {
+	return NewManager(procedureManager, NewEtcdStorage(kv))
+}
+
+func (m *managerImpl) Submit(ctx context.Context, opType string, metadata interface{}, proc procedure.Procedure) (Operation, error) {
+	now := time.Now()
+	op := Operation{
+		ID:          uuid.New().String(),
+		Type:        opType,
+		Status:      StatusPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Metadata:    metadata,
+		ProcedureID: proc.ID(),
+	}
+
+	if err := m.storage.PutOperation(ctx, op); err != nil {
+		return Operation{}, err
+	}
+
+	if err := m.procedureManager.Submit(ctx, proc); err != nil {
+		op.Status = StatusFailure
+		op.Err = err.Error()
+		op.UpdatedAt = time.Now()
+		if putErr := m.storage.PutOperation(ctx, op); putErr != nil {
+			log.Error("persist failed operation", zap.String("operationID", op.ID), zap.Error(putErr))
+		}
+		return op, err
+	}
+
+	op.Status = StatusRunning
+	op.UpdatedAt = time.Now()
+	if err := m.storage.PutOperation(ctx, op); err != nil {
+		return Operation{}, err
+	}
+
+	return op, nil
+}
+
+func (m *managerImpl) Complete(ctx context.Context, opType string, metadata interface{}, completionErr error) (Operation, error) {
+	now := time.Now()
+	op := Operation{
+		ID:        uuid.New().String(),
+		Type:      opType,
+		Status:    StatusSuccess,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Metadata:  metadata,
+	}
+	if completionErr != nil {
+		op.Status = StatusFailure
+		op.Err = completionErr.Error()
+	}
+
+	if err := m.storage.PutOperation(ctx, op); err != nil {
+		return Operation{}, err
+	}
+	return op, nil
+}
+
+func (m *managerImpl) Get(ctx context.Context, id string) (Operation, error) {
+	op, ok, err := m.storage.GetOperation(ctx, id)
+	if err != nil {
+		return Operation{}, err
+	}
+	if !ok {
+		return Operation{}, ErrOperationNotFound
+	}
+	return op, nil
+}
+
+func (m *managerImpl) List(ctx context.Context) ([]Operation, error) {
+	return m.storage.ListOperations(ctx)
+}
+
+func (m *managerImpl) Cancel(ctx context.Context, id string) error {
+	op, err := m.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if op.Status.isTerminal() {
+		return ErrOperationAlreadyFinished
+	}
+
+	if err := m.procedureManager.Cancel(ctx, op.ProcedureID); err != nil {
+		return err
+	}
+
+	op.Status = StatusCancelled
+	op.UpdatedAt = time.Now()
+	return m.storage.PutOperation(ctx, op)
+}
+
+func (m *managerImpl) Wait(ctx context.Context, id string, timeout time.Duration) (Operation, error) {
+	op, err := m.Get(ctx, id)
+	if err != nil {
+		return Operation{}, err
+	}
+	if op.Status.isTerminal() {
+		return op, nil
+	}
+
+	notify := m.subscribe(id)
+	defer m.unsubscribe(id, notify)
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		select {
+		case <-waitCtx.Done():
+			return m.Get(ctx, id)
+		case <-notify:
+			op, err := m.Get(ctx, id)
+			if err != nil {
+				return Operation{}, err
+			}
+			if op.Status.isTerminal() {
+				return op, nil
+			}
+		}
+	}
+}
+
+func (m *managerImpl) subscribe(id string) chan struct{} {
+	ch := make(chan struct{}, 1)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.waiters[id] = append(m.waiters[id], ch)
+	return ch
+}
+
+func (m *managerImpl) unsubscribe(id string, ch chan struct{}) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	chans := m.waiters[id]
+	for i, c := range chans {
+		if c == ch {
+			m.waiters[id] = append(chans[:i], chans[i+1:]...)
+			break
+		}
+	}
+}
+
+func (m *managerImpl) notify(id string) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	for _, ch := range m.waiters[id] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// reconcileLoop keeps the persisted Operation status in sync with the
+// procedures running under procedureManager, so that an Operation reflects
+// completion/failure even if no HTTP handler is actively polling it.
+func (m *managerImpl) reconcileLoop() {
+	events := m.procedureManager.Events()
+	for event := range events {
+		ctx := context.Background()
+		ops, err := m.storage.ListOperations(ctx)
+		if err != nil {
+			log.Error("list operations during reconcile", zap.Error(err))
+			continue
+		}
+
+		for _, op := range ops {
+			if op.ProcedureID != event.ProcedureID || op.Status.isTerminal() {
+				continue
+			}
+
+			op.UpdatedAt = time.Now()
+			if event.Err != nil {
+				op.Status = StatusFailure
+				op.Err = event.Err.Error()
+			} else if event.Done {
+				op.Status = StatusSuccess
+			} else {
+				op.Status = StatusRunning
+			}
+
+			if err := m.storage.PutOperation(ctx, op); err != nil {
+				log.Error("persist reconciled operation", zap.String("operationID", op.ID), zap.Error(err))
+				continue
+			}
+			m.notify(op.ID)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CeresDB/ceresmeta/server/coordinator/procedure"
+)
+
+// memStorage is an in-memory Storage used to exercise Manager without a real
+// etcd cluster; it round-trips Operation the same way etcdStorage does
+// (encoding/json), which is what NewEtcdStorage backs onto.
+type memStorage struct {
+	lock sync.Mutex
+	ops  map[string]Operation
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{ops: make(map[string]Operation)}
+}
+
+func (s *memStorage) PutOperation(_ context.Context, op Operation) error {
+	value, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+	var decoded Operation
+	if err := json.Unmarshal(value, &decoded); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.ops[op.ID] = decoded
+	return nil
+}
+
+func (s *memStorage) GetOperation(_ context.Context, id string) (Operation, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	op, ok := s.ops[id]
+	return op, ok, nil
+}
+
+func (s *memStorage) ListOperations(_ context.Context) ([]Operation, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	ops := make([]Operation, 0, len(s.ops))
+	for _, op := range s.ops {
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+func (s *memStorage) DeleteOperation(_ context.Context, id string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.ops, id)
+	return nil
+}
+
+type fakeProcedure struct{ id uint64 }
+
+func (p *fakeProcedure) ID() uint64 { return p.id }
+
+type fakeProcedureManager struct {
+	lock      sync.Mutex
+	submitErr error
+	cancelErr error
+	cancelled []uint64
+	events    chan procedure.Event
+}
+
+func newFakeProcedureManager() *fakeProcedureManager {
+	return &fakeProcedureManager{events: make(chan procedure.Event, 16)}
+}
+
+func (m *fakeProcedureManager) Submit(_ context.Context, _ procedure.Procedure) error {
+	return m.submitErr
+}
+
+func (m *fakeProcedureManager) Cancel(_ context.Context, procedureID uint64) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.cancelled = append(m.cancelled, procedureID)
+	return m.cancelErr
+}
+
+func (m *fakeProcedureManager) Events() <-chan procedure.Event {
+	return m.events
+}
+
+func TestManagerSubmitAndGet(t *testing.T) {
+	procManager := newFakeProcedureManager()
+	manager := NewManager(procManager, newMemStorage())
+
+	op, err := manager.Submit(context.Background(), "createCluster", nil, &fakeProcedure{id: 42})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if op.Status != StatusRunning {
+		t.Fatalf("Status = %v, want %v", op.Status, StatusRunning)
+	}
+	if op.ProcedureID != 42 {
+		t.Fatalf("ProcedureID = %d, want 42", op.ProcedureID)
+	}
+
+	got, err := manager.Get(context.Background(), op.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.ID != op.ID {
+		t.Fatalf("Get returned ID %s, want %s", got.ID, op.ID)
+	}
+}
+
+func TestManagerGetNotFound(t *testing.T) {
+	manager := NewManager(newFakeProcedureManager(), newMemStorage())
+
+	_, err := manager.Get(context.Background(), "missing")
+	if !errors.Is(err, ErrOperationNotFound) {
+		t.Fatalf("Get error = %v, want ErrOperationNotFound", err)
+	}
+}
+
+func TestManagerReconcileMatchesByProcedureID(t *testing.T) {
+	procManager := newFakeProcedureManager()
+	manager := NewManager(procManager, newMemStorage())
+
+	op, err := manager.Submit(context.Background(), "createCluster", nil, &fakeProcedure{id: 7})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	procManager.events <- procedure.Event{ProcedureID: 7, Done: true}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		got, err := manager.Get(context.Background(), op.ID)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if got.Status == StatusSuccess {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Status = %v, want %v after reconcile", got.Status, StatusSuccess)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestManagerCancelAlreadyFinished(t *testing.T) {
+	procManager := newFakeProcedureManager()
+	manager := NewManager(procManager, newMemStorage())
+
+	op, err := manager.Submit(context.Background(), "createCluster", nil, &fakeProcedure{id: 1})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	procManager.events <- procedure.Event{ProcedureID: 1, Done: true}
+	deadline := time.Now().Add(time.Second)
+	for {
+		got, _ := manager.Get(context.Background(), op.ID)
+		if got.Status.isTerminal() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("operation never reached a terminal state")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := manager.Cancel(context.Background(), op.ID); !errors.Is(err, ErrOperationAlreadyFinished) {
+		t.Fatalf("Cancel error = %v, want ErrOperationAlreadyFinished", err)
+	}
+}
+
+func TestManagerWaitTimesOut(t *testing.T) {
+	manager := NewManager(newFakeProcedureManager(), newMemStorage())
+
+	op, err := manager.Submit(context.Background(), "createCluster", nil, &fakeProcedure{id: 99})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	got, err := manager.Wait(context.Background(), op.ID, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if got.Status != StatusRunning {
+		t.Fatalf("Status = %v, want %v", got.Status, StatusRunning)
+	}
+}
@@ -0,0 +1,14 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+package procedure
+
+import "sync/atomic"
+
+// idAllocator hands out unique, monotonically increasing procedure IDs.
+type idAllocator struct {
+	counter uint64
+}
+
+func (a *idAllocator) next() uint64 {
+	return atomic.AddUint64(&a.counter, 1)
+}
@@ -0,0 +1,119 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+// Package procedure defines the long-running, resumable distributed
+// operations (leader transfer, shard split, cluster creation, ...) that the
+// HTTP API submits to procedureManager: the Procedure and Manager interfaces,
+// the request types, and a Factory that builds placeholder Procedures.
+package procedure
+
+import (
+	"context"
+
+	"github.com/CeresDB/ceresmeta/server/storage"
+)
+
+// Procedure is a single long-running, resumable distributed operation.
+type Procedure interface {
+	// ID uniquely identifies the procedure so a Manager can track and
+	// cancel it, and so an Event can be attributed back to it.
+	ID() uint64
+}
+
+// Event reports a procedure's execution progress to subscribers, e.g. the
+// operations package (to reconcile Operation status) and the events.Bus (to
+// publish it over SSE).
+type Event struct {
+	ProcedureID uint64
+	Done        bool
+	Err         error
+}
+
+// Manager tracks submitted Procedures, running each to completion (or
+// failure) and reporting progress via Events.
+type Manager interface {
+	Submit(ctx context.Context, procedure Procedure) error
+	Cancel(ctx context.Context, procedureID uint64) error
+	// Events streams state changes for every procedure this Manager runs.
+	Events() <-chan Event
+}
+
+// TransferLeaderRequest describes a shard leader transfer.
+type TransferLeaderRequest struct {
+	ClusterName       string
+	ShardID           storage.ShardID
+	OldLeaderNodeName string
+	NewLeaderNodeName string
+}
+
+// SplitRequest describes splitting TableNames out of ShardID into NewShardID
+// on TargetNodeName.
+type SplitRequest struct {
+	ClusterName    string
+	SchemaName     string
+	TableNames     []string
+	ShardID        storage.ShardID
+	NewShardID     storage.ShardID
+	TargetNodeName string
+}
+
+// DropTableRequest describes dropping a single table.
+type DropTableRequest struct {
+	ClusterName string
+	SchemaName  string
+	Table       string
+}
+
+// Factory builds Procedures. Each Create* method allocates a unique
+// procedure ID and returns a Procedure ready for Manager.Submit. The returned
+// Procedures carry only that ID and their request; no execution steps are
+// defined in this package.
+type Factory struct {
+	idAlloc idAllocator
+}
+
+// NewFactory creates a Factory.
+func NewFactory() *Factory {
+	return &Factory{}
+}
+
+func (f *Factory) CreateTransferLeaderProcedure(_ context.Context, request TransferLeaderRequest) (Procedure, error) {
+	return &genericProcedure{id: f.idAlloc.next(), kind: "transferLeader", request: request}, nil
+}
+
+func (f *Factory) CreateSplitProcedure(_ context.Context, request SplitRequest) (Procedure, error) {
+	return &genericProcedure{id: f.idAlloc.next(), kind: "split", request: request}, nil
+}
+
+func (f *Factory) CreateDropTableProcedure(_ context.Context, request DropTableRequest) (Procedure, error) {
+	return &genericProcedure{id: f.idAlloc.next(), kind: "dropTable", request: request}, nil
+}
+
+// CreateClusterProcedure builds the procedure that provisions a new cluster
+// with nodeCount nodes, replicationFactor replicas per shard, and
+// shardTotal total shards.
+func (f *Factory) CreateClusterProcedure(_ context.Context, clusterName string, nodeCount, replicationFactor, shardTotal uint32) (Procedure, error) {
+	return &genericProcedure{id: f.idAlloc.next(), kind: "createCluster", request: struct {
+		ClusterName       string
+		NodeCount         uint32
+		ReplicationFactor uint32
+		ShardTotal        uint32
+	}{clusterName, nodeCount, replicationFactor, shardTotal}}, nil
+}
+
+// CreateDeleteClusterProcedure builds the procedure that tears down
+// clusterName.
+func (f *Factory) CreateDeleteClusterProcedure(_ context.Context, clusterName string) (Procedure, error) {
+	return &genericProcedure{id: f.idAlloc.next(), kind: "deleteCluster", request: clusterName}, nil
+}
+
+// genericProcedure is a placeholder Procedure carrying just enough state
+// (a unique ID and its originating request) for Manager.Submit and Events
+// to track it; the distributed execution steps for each kind are out of
+// scope for the HTTP API package.
+type genericProcedure struct {
+	id      uint64
+	kind    string
+	request interface{}
+}
+
+func (p *genericProcedure) ID() uint64 { return p.id }
@@ -0,0 +1,29 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+package procedure
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFactoryCreateProceduresAllocateUniqueIDs(t *testing.T) {
+	f := NewFactory()
+
+	transfer, err := f.CreateTransferLeaderProcedure(context.Background(), TransferLeaderRequest{ClusterName: "test"})
+	if err != nil {
+		t.Fatalf("CreateTransferLeaderProcedure: %v", err)
+	}
+
+	cluster, err := f.CreateClusterProcedure(context.Background(), "test", 3, 2, 4)
+	if err != nil {
+		t.Fatalf("CreateClusterProcedure: %v", err)
+	}
+
+	if transfer.ID() == cluster.ID() {
+		t.Fatalf("expected unique procedure IDs, got %d for both", transfer.ID())
+	}
+	if transfer.ID() == 0 || cluster.ID() == 0 {
+		t.Fatal("procedure IDs must not be the zero value")
+	}
+}
@@ -9,10 +9,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/CeresDB/ceresmeta/pkg/coderr"
 	"github.com/CeresDB/ceresmeta/pkg/log"
 	"github.com/CeresDB/ceresmeta/server/cluster"
+	"github.com/CeresDB/ceresmeta/server/coordinator/operations"
 	"github.com/CeresDB/ceresmeta/server/coordinator/procedure"
 	"github.com/CeresDB/ceresmeta/server/storage"
 	"go.uber.org/zap"
@@ -20,44 +23,88 @@ import (
 
 const (
 	statusSuccess string = "success"
-	statusError   string = "error"
 
 	apiPrefix string = "/api/v1"
+
+	// defaultWaitTimeout is used when a /operations/{id}/wait request does not
+	// specify a timeout query parameter.
+	defaultWaitTimeout = 30 * time.Second
 )
 
 type API struct {
-	clusterManager   cluster.Manager
-	procedureManager procedure.Manager
-	procedureFactory *procedure.Factory
+	clusterManager    cluster.Manager
+	procedureManager  procedure.Manager
+	procedureFactory  *procedure.Factory
+	operationsManager operations.Manager
+	eventsBus         *eventsBus
 
 	forwardClient *ForwardClient
 }
 
-func NewAPI(procedureManager procedure.Manager, procedureFactory *procedure.Factory, clusterManager cluster.Manager, forwardClient *ForwardClient) *API {
+// NewAPI wires the operations Manager to kv, the same etcd-backed
+// key-value store used for cluster/shard/procedure metadata, so Operations
+// survive a leader failover.
+func NewAPI(procedureManager procedure.Manager, procedureFactory *procedure.Factory, clusterManager cluster.Manager, kv storage.KV, forwardClient *ForwardClient) *API {
 	return &API{
-		procedureManager: procedureManager,
-		procedureFactory: procedureFactory,
-		clusterManager:   clusterManager,
-		forwardClient:    forwardClient,
+		procedureManager:  procedureManager,
+		procedureFactory:  procedureFactory,
+		clusterManager:    clusterManager,
+		operationsManager: operations.NewEtcdBackedManager(procedureManager, kv),
+		eventsBus:         newEventsBus(procedureManager, clusterManager),
+		forwardClient:     forwardClient,
 	}
 }
 
 func (a *API) NewAPIRouter() *Router {
-	router := New().WithPrefix(apiPrefix).WithInstrumentation(printRequestInsmt)
+	router := New().WithPrefix(apiPrefix).WithInstrumentation(printRequestInsmt).WithLeaderForwarding(a.forwardClient).WithMetrics().WithTracing()
 
-	// Register post API.
+	// Register post API. getShardTables reads Cluster.GetShardTables, which
+	// has no replication guarantee backing it, so - like the mutating
+	// endpoints below - it is forwarded to the leader rather than risking
+	// stale data off a follower.
 	router.Post("/getShardTables", a.getShardTables)
 	router.Post("/transferLeader", a.transferLeader)
 	router.Post("/split", a.split)
 	router.Post("/route", a.route)
 	router.Post("/dropTable", a.dropTable)
 
+	// Register operations API. Reads are served locally; only cancellation
+	// needs to run against the leader's procedureManager.
+	router.Get("/operations", a.listOperations, NoForward())
+	router.Get("/operations/{id}", a.getOperation, NoForward())
+	router.Delete("/operations/{id}", a.cancelOperation)
+	router.Get("/operations/{id}/wait", a.waitOperation, NoForward())
+
+	// Register SSE event stream.
+	router.Get("/events", a.events, NoForward())
+
+	// Register cluster admin API.
+	router.Get("/clusters", a.listClusters, NoForward())
+	router.Get("/clusters/{name}", a.getCluster, NoForward())
+	router.Post("/clusters", a.createCluster)
+	router.Delete("/clusters/{name}", a.deleteCluster)
+	router.Get("/clusters/{name}/nodes", a.listClusterNodes, NoForward())
+	router.Get("/clusters/{name}/shards", a.listClusterShards, NoForward())
+	router.Get("/clusters/{name}/shards/{id}", a.getClusterShard, NoForward())
+	router.Get("/clusters/{name}/schemas", a.listClusterSchemas, NoForward())
+	router.Get("/clusters/{name}/schemas/{schema}/tables", a.listSchemaTables, NoForward())
+
+	// Expose Prometheus metrics on the same router as the rest of the API.
+	router.Get("/metrics", metricsHandler(), NoForward())
+
 	return router
 }
 
-// printRequestInsmt used for printing every request information.
+// printRequestInsmt used for printing every request information. It also
+// generates a request ID, attaches it to the request context so handlers
+// and respondError/respond can echo it back, and sets it on the response
+// header for correlation even if the handler panics before responding.
 func printRequestInsmt(handlerName string, handler http.HandlerFunc) http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
+		requestID := newRequestID()
+		request = request.WithContext(withRequestID(request.Context(), requestID))
+		writer.Header().Set(requestIDHeader, requestID)
+
 		body := ""
 		bodyByte, err := io.ReadAll(request.Body)
 		if err == nil {
@@ -65,19 +112,18 @@ func printRequestInsmt(handlerName string, handler http.HandlerFunc) http.Handle
 			newBody := io.NopCloser(bytes.NewReader(bodyByte))
 			request.Body = newBody
 		}
-		log.Info("receive http request", zap.String("handlerName", handlerName), zap.String("client host", request.RemoteAddr), zap.String("method", request.Method), zap.String("params", request.Form.Encode()), zap.String("body", body))
+		log.Info("receive http request", zap.String("handlerName", handlerName), zap.String("requestId", requestID), zap.String("client host", request.RemoteAddr), zap.String("method", request.Method), zap.String("params", request.Form.Encode()), zap.String("body", body))
 		handler.ServeHTTP(writer, request)
 	}
 }
 
 type response struct {
-	Status string      `json:"status"`
-	Data   interface{} `json:"data,omitempty"`
-	Error  string      `json:"error,omitempty"`
-	Msg    string      `json:"msg,omitempty"`
+	Status    string      `json:"status"`
+	Data      interface{} `json:"data,omitempty"`
+	RequestID string      `json:"requestId,omitempty"`
 }
 
-func (a *API) respondForward(w http.ResponseWriter, response *http.Response) {
+func respondForward(w http.ResponseWriter, response *http.Response) {
 	b, err := io.ReadAll(response.Body)
 	if err != nil {
 		log.Error("read resp failed", zap.Error(err))
@@ -96,11 +142,11 @@ func (a *API) respondForward(w http.ResponseWriter, response *http.Response) {
 	}
 }
 
-func (a *API) respond(w http.ResponseWriter, data interface{}) {
-	statusMessage := statusSuccess
+func (a *API) respond(w http.ResponseWriter, req *http.Request, data interface{}) {
 	b, err := json.Marshal(&response{
-		Status: statusMessage,
-		Data:   data,
+		Status:    statusSuccess,
+		Data:      data,
+		RequestID: requestIDFromContext(req.Context()),
 	})
 	if err != nil {
 		log.Error("error marshaling json response", zap.Error(err))
@@ -115,11 +161,14 @@ func (a *API) respond(w http.ResponseWriter, data interface{}) {
 	}
 }
 
-func (a *API) respondError(w http.ResponseWriter, apiErr coderr.CodeError, msg string) {
+// respondOperation replies with 202 Accepted and a Location header pointing
+// at the operation, so the caller can poll or wait for the outcome of what
+// would otherwise be an opaque long-running procedure submission.
+func (a *API) respondOperation(w http.ResponseWriter, req *http.Request, op operations.Operation) {
 	b, err := json.Marshal(&response{
-		Status: statusError,
-		Error:  apiErr.Error(),
-		Msg:    msg,
+		Status:    statusSuccess,
+		Data:      op,
+		RequestID: requestIDFromContext(req.Context()),
 	})
 	if err != nil {
 		log.Error("error marshaling json response", zap.Error(err))
@@ -128,7 +177,47 @@ func (a *API) respondError(w http.ResponseWriter, apiErr coderr.CodeError, msg s
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(apiErr.Code().ToHTTPCode())
+	w.Header().Set("Location", fmt.Sprintf("%s/operations/%s", apiPrefix, op.ID))
+	w.WriteHeader(http.StatusAccepted)
+	if n, err := w.Write(b); err != nil {
+		log.Error("error writing response", zap.Int("msg", n), zap.Error(err))
+	}
+}
+
+// respondError replies with an RFC 7807 application/problem+json body so
+// that callers can distinguish error cases (e.g. "shard not found" from
+// "cluster not found") on the stable `type` URI instead of string-matching a
+// human-readable message. The numeric `code` field is the coderr code, which
+// such errors can share (both of those are NotFound). extensions carries structured
+// context, e.g. the cluster name or shard ID that the error refers to.
+func (a *API) respondError(w http.ResponseWriter, req *http.Request, apiErr coderr.CodeError, detail string, extensions ...map[string]interface{}) {
+	merged := map[string]interface{}{}
+	for _, ext := range extensions {
+		for k, v := range ext {
+			merged[k] = v
+		}
+	}
+
+	problem := problemDetails{
+		Type:       errorType(apiErr),
+		Title:      apiErr.Error(),
+		Status:     apiErr.Code().ToHTTPCode(),
+		Detail:     detail,
+		Instance:   req.URL.Path,
+		Code:       int(apiErr.Code()),
+		RequestID:  requestIDFromContext(req.Context()),
+		Extensions: merged,
+	}
+
+	b, err := json.Marshal(problem)
+	if err != nil {
+		log.Error("error marshaling json response", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
 	if n, err := w.Write(b); err != nil {
 		log.Error("error writing response", zap.Int("msg", n), zap.Error(err))
 	}
@@ -144,7 +233,7 @@ func (a *API) getShardTables(writer http.ResponseWriter, req *http.Request) {
 	var getShardTables GetShardTables
 	err := json.NewDecoder(req.Body).Decode(&getShardTables)
 	if err != nil {
-		a.respondError(writer, ErrParseRequest, "decode request body failed")
+		a.respondError(writer, req, ErrParseRequest, "decode request body failed")
 		return
 	}
 	log.Info("get shard tables request", zap.String("request", fmt.Sprintf("%+v", getShardTables)))
@@ -152,7 +241,7 @@ func (a *API) getShardTables(writer http.ResponseWriter, req *http.Request) {
 	c, err := a.clusterManager.GetCluster(req.Context(), getShardTables.ClusterName)
 	if err != nil {
 		log.Error("get cluster failed", zap.String("clusterName", getShardTables.ClusterName), zap.Error(err))
-		a.respondError(writer, ErrGetCluster, fmt.Sprintf("get cluster failed, clusterName:%s", getShardTables.ClusterName))
+		a.respondError(writer, req, ErrGetCluster, fmt.Sprintf("get cluster failed, clusterName:%s", getShardTables.ClusterName), map[string]interface{}{"clusterName": getShardTables.ClusterName})
 		return
 	}
 
@@ -162,7 +251,7 @@ func (a *API) getShardTables(writer http.ResponseWriter, req *http.Request) {
 	}
 
 	shardTables := c.GetShardTables(shardIDs, getShardTables.NodeName)
-	a.respond(writer, shardTables)
+	a.respond(writer, req, shardTables)
 }
 
 type TransferLeaderRequest struct {
@@ -177,7 +266,7 @@ func (a *API) transferLeader(writer http.ResponseWriter, req *http.Request) {
 	err := json.NewDecoder(req.Body).Decode(&transferLeaderRequest)
 	if err != nil {
 		log.Error("decode request body failed", zap.Error(err))
-		a.respondError(writer, ErrParseRequest, "decode request body failed")
+		a.respondError(writer, req, ErrParseRequest, "decode request body failed")
 		return
 	}
 	log.Info("transfer leader request", zap.String("request", fmt.Sprintf("%+v", transferLeaderRequest)))
@@ -190,17 +279,17 @@ func (a *API) transferLeader(writer http.ResponseWriter, req *http.Request) {
 	})
 	if err != nil {
 		log.Error("create transfer leader procedure", zap.Error(err))
-		a.respondError(writer, ErrCreateProcedure, "create transfer leader procedure")
+		a.respondError(writer, req, ErrCreateProcedure, "create transfer leader procedure")
 		return
 	}
-	err = a.procedureManager.Submit(req.Context(), transferLeaderProcedure)
+	op, err := a.operationsManager.Submit(req.Context(), "transferLeader", transferLeaderRequest, transferLeaderProcedure)
 	if err != nil {
 		log.Error("submit transfer leader procedure", zap.Error(err))
-		a.respondError(writer, ErrSubmitProcedure, "submit transfer leader procedure")
+		a.respondError(writer, req, ErrSubmitProcedure, "submit transfer leader procedure")
 		return
 	}
 
-	a.respond(writer, nil)
+	a.respondOperation(writer, req, op)
 }
 
 type RouteRequest struct {
@@ -209,24 +298,14 @@ type RouteRequest struct {
 	Tables      []string `json:"table"`
 }
 
+// route no longer forwards to the leader itself: that is now handled
+// transparently by the Router's WithLeaderForwarding decorator.
 func (a *API) route(writer http.ResponseWriter, req *http.Request) {
-	resp, isLeader, err := a.forwardClient.forwardToLeader(req)
-	if err != nil {
-		log.Error("forward to leader failed", zap.Error(err))
-		a.respondError(writer, ErrForwardToLeader, "forward to leader failed")
-		return
-	}
-
-	if !isLeader {
-		a.respondForward(writer, resp)
-		return
-	}
-
 	var routeRequest RouteRequest
-	err = json.NewDecoder(req.Body).Decode(&routeRequest)
+	err := json.NewDecoder(req.Body).Decode(&routeRequest)
 	if err != nil {
 		log.Error("decode request body failed", zap.Error(err))
-		a.respondError(writer, ErrParseRequest, "decode request body failed")
+		a.respondError(writer, req, ErrParseRequest, "decode request body failed")
 		return
 	}
 	log.Info("route request", zap.String("request", fmt.Sprintf("%+v", routeRequest)))
@@ -234,11 +313,11 @@ func (a *API) route(writer http.ResponseWriter, req *http.Request) {
 	result, err := a.clusterManager.RouteTables(context.Background(), routeRequest.ClusterName, routeRequest.SchemaName, routeRequest.Tables)
 	if err != nil {
 		log.Error("route tables failed", zap.Error(err))
-		a.respondError(writer, ErrRouteTable, "route tables failed")
+		a.respondError(writer, req, ErrRouteTable, "route tables failed")
 		return
 	}
 
-	a.respond(writer, result)
+	a.respond(writer, req, result)
 }
 
 type DropTableRequest struct {
@@ -252,18 +331,23 @@ func (a *API) dropTable(writer http.ResponseWriter, req *http.Request) {
 	err := json.NewDecoder(req.Body).Decode(&dropTableRequest)
 	if err != nil {
 		log.Error("decode request body failed", zap.Error(err))
-		a.respondError(writer, ErrParseRequest, "decode request body failed")
+		a.respondError(writer, req, ErrParseRequest, "decode request body failed")
 		return
 	}
 	log.Info("drop table reqeust", zap.String("request", fmt.Sprintf("%+v", dropTableRequest)))
 
-	if err := a.clusterManager.DropTable(context.Background(), dropTableRequest.ClusterName, dropTableRequest.SchemaName, dropTableRequest.Table); err != nil {
-		log.Error("cluster drop table failed", zap.Error(err))
-		a.respondError(writer, ErrDropTable, "drop table failed")
+	dropErr := a.clusterManager.DropTable(context.Background(), dropTableRequest.ClusterName, dropTableRequest.SchemaName, dropTableRequest.Table)
+	op, err := a.operationsManager.Complete(context.Background(), "dropTable", dropTableRequest, dropErr)
+	if err != nil {
+		log.Error("record drop table operation", zap.Error(err))
+		a.respondError(writer, req, ErrDropTable, "drop table failed")
 		return
 	}
+	if dropErr != nil {
+		log.Error("cluster drop table failed", zap.Error(dropErr))
+	}
 
-	a.respond(writer, nil)
+	a.respondOperation(writer, req, op)
 }
 
 type SplitRequest struct {
@@ -279,7 +363,7 @@ func (a *API) split(writer http.ResponseWriter, req *http.Request) {
 	err := json.NewDecoder(req.Body).Decode(&splitRequest)
 	if err != nil {
 		log.Error("decode request body failed", zap.Error(err))
-		a.respondError(writer, ErrParseRequest, "")
+		a.respondError(writer, req, ErrParseRequest, "")
 		return
 	}
 	ctx := context.Background()
@@ -287,14 +371,14 @@ func (a *API) split(writer http.ResponseWriter, req *http.Request) {
 	c, err := a.clusterManager.GetCluster(ctx, splitRequest.ClusterName)
 	if err != nil {
 		log.Error("cluster not found", zap.String("clusterName", splitRequest.ClusterName), zap.Error(err))
-		a.respondError(writer, cluster.ErrClusterNotFound, "cluster not found")
+		a.respondError(writer, req, cluster.ErrClusterNotFound, "cluster not found", map[string]interface{}{"clusterName": splitRequest.ClusterName})
 		return
 	}
 
 	newShardID, err := c.AllocShardID(ctx)
 	if err != nil {
 		log.Error("alloc shard id failed")
-		a.respondError(writer, ErrAllocShardID, "alloc shard id failed")
+		a.respondError(writer, req, ErrAllocShardID, "alloc shard id failed")
 		return
 	}
 
@@ -308,15 +392,81 @@ func (a *API) split(writer http.ResponseWriter, req *http.Request) {
 	})
 	if err != nil {
 		log.Error("create split procedure", zap.Error(err))
-		a.respondError(writer, ErrCreateProcedure, "create split procedure")
+		a.respondError(writer, req, ErrCreateProcedure, "create split procedure")
 		return
 	}
 
-	if err := a.procedureManager.Submit(ctx, splitProcedure); err != nil {
+	splitMetadata := struct {
+		SplitRequest
+		NewShardID uint32 `json:"newShardID"`
+	}{SplitRequest: splitRequest, NewShardID: uint32(newShardID)}
+	op, err := a.operationsManager.Submit(ctx, "split", splitMetadata, splitProcedure)
+	if err != nil {
 		log.Error("submit split procedure", zap.Error(err))
-		a.respondError(writer, ErrSubmitProcedure, "submit split procedure")
+		a.respondError(writer, req, ErrSubmitProcedure, "submit split procedure")
+		return
+	}
+
+	a.respondOperation(writer, req, op)
+}
+
+// listOperations handles GET /operations.
+func (a *API) listOperations(writer http.ResponseWriter, req *http.Request) {
+	ops, err := a.operationsManager.List(req.Context())
+	if err != nil {
+		log.Error("list operations failed", zap.Error(err))
+		a.respondError(writer, req, operationError(err, ErrGetOperation), "list operations failed")
+		return
+	}
+
+	a.respond(writer, req, ops)
+}
+
+// getOperation handles GET /operations/{id}.
+func (a *API) getOperation(writer http.ResponseWriter, req *http.Request) {
+	id := pathParam(req, "id")
+	op, err := a.operationsManager.Get(req.Context(), id)
+	if err != nil {
+		log.Error("get operation failed", zap.String("operationID", id), zap.Error(err))
+		a.respondError(writer, req, operationError(err, ErrGetOperation), fmt.Sprintf("get operation failed, id:%s", id))
+		return
+	}
+
+	a.respond(writer, req, op)
+}
+
+// cancelOperation handles DELETE /operations/{id}.
+func (a *API) cancelOperation(writer http.ResponseWriter, req *http.Request) {
+	id := pathParam(req, "id")
+	if err := a.operationsManager.Cancel(req.Context(), id); err != nil {
+		log.Error("cancel operation failed", zap.String("operationID", id), zap.Error(err))
+		a.respondError(writer, req, operationError(err, ErrCancelOperation), fmt.Sprintf("cancel operation failed, id:%s", id))
+		return
+	}
+
+	a.respond(writer, req, nil)
+}
+
+// waitOperation handles GET /operations/{id}/wait?timeout=5s and long-polls
+// until the operation reaches a terminal state or the timeout elapses.
+func (a *API) waitOperation(writer http.ResponseWriter, req *http.Request) {
+	id := pathParam(req, "id")
+
+	timeout := defaultWaitTimeout
+	if raw := req.URL.Query().Get("timeout"); raw != "" {
+		if parsed, err := time.ParseDuration(raw); err == nil {
+			timeout = parsed
+		} else if seconds, err := strconv.Atoi(raw); err == nil {
+			timeout = time.Duration(seconds) * time.Second
+		}
+	}
+
+	op, err := a.operationsManager.Wait(req.Context(), id, timeout)
+	if err != nil {
+		log.Error("wait operation failed", zap.String("operationID", id), zap.Error(err))
+		a.respondError(writer, req, operationError(err, ErrGetOperation), fmt.Sprintf("wait operation failed, id:%s", id))
 		return
 	}
 
-	a.respond(writer, newShardID)
+	a.respond(writer, req, op)
 }
@@ -0,0 +1,189 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/CeresDB/ceresmeta/pkg/log"
+	"github.com/CeresDB/ceresmeta/server/cluster"
+	"github.com/CeresDB/ceresmeta/server/storage"
+	"go.uber.org/zap"
+)
+
+// listClusters handles GET /clusters.
+func (a *API) listClusters(writer http.ResponseWriter, req *http.Request) {
+	clusters, err := a.clusterManager.ListClusters(req.Context())
+	if err != nil {
+		log.Error("list clusters failed", zap.Error(err))
+		a.respondError(writer, req, ErrGetCluster, "list clusters failed")
+		return
+	}
+
+	a.respond(writer, req, clusters)
+}
+
+// getCluster handles GET /clusters/{name}.
+func (a *API) getCluster(writer http.ResponseWriter, req *http.Request) {
+	name := pathParam(req, "name")
+	c, err := a.clusterManager.GetCluster(req.Context(), name)
+	if err != nil {
+		log.Error("get cluster failed", zap.String("clusterName", name), zap.Error(err))
+		a.respondError(writer, req, cluster.ErrClusterNotFound, fmt.Sprintf("get cluster failed, clusterName:%s", name), map[string]interface{}{"clusterName": name})
+		return
+	}
+
+	a.respond(writer, req, c)
+}
+
+// CreateClusterRequest is the body of POST /clusters.
+type CreateClusterRequest struct {
+	Name              string `json:"name"`
+	NodeCount         uint32 `json:"nodeCount"`
+	ReplicationFactor uint32 `json:"replicationFactor"`
+	ShardTotal        uint32 `json:"shardTotal"`
+}
+
+// createCluster handles POST /clusters.
+func (a *API) createCluster(writer http.ResponseWriter, req *http.Request) {
+	var createClusterRequest CreateClusterRequest
+	if err := json.NewDecoder(req.Body).Decode(&createClusterRequest); err != nil {
+		log.Error("decode request body failed", zap.Error(err))
+		a.respondError(writer, req, ErrParseRequest, "decode request body failed")
+		return
+	}
+	log.Info("create cluster request", zap.String("request", fmt.Sprintf("%+v", createClusterRequest)))
+
+	createProcedure, err := a.procedureFactory.CreateClusterProcedure(req.Context(), createClusterRequest.Name, createClusterRequest.NodeCount, createClusterRequest.ReplicationFactor, createClusterRequest.ShardTotal)
+	if err != nil {
+		log.Error("create cluster procedure failed", zap.Error(err))
+		a.respondError(writer, req, ErrCreateProcedure, "create cluster procedure failed")
+		return
+	}
+
+	op, err := a.operationsManager.Submit(req.Context(), "createCluster", createClusterRequest, createProcedure)
+	if err != nil {
+		log.Error("submit create cluster procedure failed", zap.Error(err))
+		a.respondError(writer, req, ErrSubmitProcedure, "submit create cluster procedure failed")
+		return
+	}
+
+	a.respondOperation(writer, req, op)
+}
+
+// deleteCluster handles DELETE /clusters/{name}.
+func (a *API) deleteCluster(writer http.ResponseWriter, req *http.Request) {
+	name := pathParam(req, "name")
+
+	deleteProcedure, err := a.procedureFactory.CreateDeleteClusterProcedure(req.Context(), name)
+	if err != nil {
+		log.Error("create delete cluster procedure failed", zap.String("clusterName", name), zap.Error(err))
+		a.respondError(writer, req, ErrCreateProcedure, "create delete cluster procedure failed")
+		return
+	}
+
+	op, err := a.operationsManager.Submit(req.Context(), "deleteCluster", name, deleteProcedure)
+	if err != nil {
+		log.Error("submit delete cluster procedure failed", zap.String("clusterName", name), zap.Error(err))
+		a.respondError(writer, req, ErrSubmitProcedure, "submit delete cluster procedure failed")
+		return
+	}
+
+	a.respondOperation(writer, req, op)
+}
+
+// listClusterNodes handles GET /clusters/{name}/nodes.
+func (a *API) listClusterNodes(writer http.ResponseWriter, req *http.Request) {
+	name := pathParam(req, "name")
+	c, err := a.clusterManager.GetCluster(req.Context(), name)
+	if err != nil {
+		log.Error("get cluster failed", zap.String("clusterName", name), zap.Error(err))
+		a.respondError(writer, req, cluster.ErrClusterNotFound, fmt.Sprintf("get cluster failed, clusterName:%s", name), map[string]interface{}{"clusterName": name})
+		return
+	}
+
+	a.respond(writer, req, c.ListNodes())
+}
+
+// listClusterShards handles GET /clusters/{name}/shards.
+func (a *API) listClusterShards(writer http.ResponseWriter, req *http.Request) {
+	name := pathParam(req, "name")
+	c, err := a.clusterManager.GetCluster(req.Context(), name)
+	if err != nil {
+		log.Error("get cluster failed", zap.String("clusterName", name), zap.Error(err))
+		a.respondError(writer, req, cluster.ErrClusterNotFound, fmt.Sprintf("get cluster failed, clusterName:%s", name), map[string]interface{}{"clusterName": name})
+		return
+	}
+
+	a.respond(writer, req, c.ListShards())
+}
+
+// getClusterShard handles GET /clusters/{name}/shards/{id}.
+func (a *API) getClusterShard(writer http.ResponseWriter, req *http.Request) {
+	name := pathParam(req, "name")
+	shardID, err := parseShardID(pathParam(req, "id"))
+	if err != nil {
+		a.respondError(writer, req, ErrParseRequest, "parse shard id failed")
+		return
+	}
+
+	c, err := a.clusterManager.GetCluster(req.Context(), name)
+	if err != nil {
+		log.Error("get cluster failed", zap.String("clusterName", name), zap.Error(err))
+		a.respondError(writer, req, cluster.ErrClusterNotFound, fmt.Sprintf("get cluster failed, clusterName:%s", name), map[string]interface{}{"clusterName": name})
+		return
+	}
+
+	shard, ok := c.GetShard(shardID)
+	if !ok {
+		a.respondError(writer, req, ErrGetShard, fmt.Sprintf("shard not found, clusterName:%s, shardID:%d", name, shardID), map[string]interface{}{"clusterName": name, "shardID": shardID})
+		return
+	}
+
+	a.respond(writer, req, shard)
+}
+
+// listClusterSchemas handles GET /clusters/{name}/schemas.
+func (a *API) listClusterSchemas(writer http.ResponseWriter, req *http.Request) {
+	name := pathParam(req, "name")
+	c, err := a.clusterManager.GetCluster(req.Context(), name)
+	if err != nil {
+		log.Error("get cluster failed", zap.String("clusterName", name), zap.Error(err))
+		a.respondError(writer, req, cluster.ErrClusterNotFound, fmt.Sprintf("get cluster failed, clusterName:%s", name), map[string]interface{}{"clusterName": name})
+		return
+	}
+
+	a.respond(writer, req, c.ListSchemas())
+}
+
+// listSchemaTables handles GET /clusters/{name}/schemas/{schema}/tables.
+func (a *API) listSchemaTables(writer http.ResponseWriter, req *http.Request) {
+	name := pathParam(req, "name")
+	schema := pathParam(req, "schema")
+	c, err := a.clusterManager.GetCluster(req.Context(), name)
+	if err != nil {
+		log.Error("get cluster failed", zap.String("clusterName", name), zap.Error(err))
+		a.respondError(writer, req, cluster.ErrClusterNotFound, fmt.Sprintf("get cluster failed, clusterName:%s", name), map[string]interface{}{"clusterName": name})
+		return
+	}
+
+	tables, err := c.ListSchemaTables(schema)
+	if err != nil {
+		log.Error("list schema tables failed", zap.String("clusterName", name), zap.String("schema", schema), zap.Error(err))
+		a.respondError(writer, req, cluster.ErrSchemaNotFound, fmt.Sprintf("list schema tables failed, clusterName:%s, schema:%s", name, schema), map[string]interface{}{"clusterName": name, "schema": schema})
+		return
+	}
+
+	a.respond(writer, req, tables)
+}
+
+func parseShardID(raw string) (storage.ShardID, error) {
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return storage.ShardID(id), nil
+}
@@ -0,0 +1,21 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+package http
+
+import "testing"
+
+func TestParseShardIDRejectsTrailingGarbage(t *testing.T) {
+	if _, err := parseShardID("12abc"); err == nil {
+		t.Fatal("parseShardID(\"12abc\") should reject trailing garbage, got nil error")
+	}
+}
+
+func TestParseShardIDAcceptsPlainNumber(t *testing.T) {
+	id, err := parseShardID("12")
+	if err != nil {
+		t.Fatalf("parseShardID(\"12\"): %v", err)
+	}
+	if id != 12 {
+		t.Fatalf("id = %d, want 12", id)
+	}
+}
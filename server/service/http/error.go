@@ -0,0 +1,112 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/CeresDB/ceresmeta/pkg/coderr"
+	"github.com/CeresDB/ceresmeta/server/cluster"
+	"github.com/CeresDB/ceresmeta/server/coordinator/operations"
+)
+
+var (
+	ErrParseRequest    = coderr.NewCodeError(coderr.Invalid, "parse request")
+	ErrGetCluster      = coderr.NewCodeError(coderr.Internal, "get cluster")
+	ErrCreateProcedure = coderr.NewCodeError(coderr.Internal, "create procedure")
+	ErrSubmitProcedure = coderr.NewCodeError(coderr.Internal, "submit procedure")
+	ErrForwardToLeader = coderr.NewCodeError(coderr.Internal, "forward to leader")
+	ErrRouteTable      = coderr.NewCodeError(coderr.Internal, "route table")
+	ErrDropTable       = coderr.NewCodeError(coderr.Internal, "drop table")
+	ErrAllocShardID    = coderr.NewCodeError(coderr.Internal, "alloc shard id")
+
+	ErrGetOperation             = coderr.NewCodeError(coderr.Internal, "get operation")
+	ErrOperationNotFound        = coderr.NewCodeError(coderr.NotFound, "operation not found")
+	ErrOperationAlreadyFinished = coderr.NewCodeError(coderr.Invalid, "operation already finished")
+	ErrCancelOperation          = coderr.NewCodeError(coderr.Internal, "cancel operation")
+
+	ErrStreamUnsupported = coderr.NewCodeError(coderr.Internal, "streaming unsupported")
+
+	ErrGetShard = coderr.NewCodeError(coderr.NotFound, "get shard")
+)
+
+// operationError maps an error returned by operationsManager to the
+// coderr.CodeError whose code/type best describes it, so that callers can
+// distinguish "not found" from "already finished" from an internal failure
+// on the stable code/type fields instead of string-matching detail.
+// fallback is returned for any error that isn't one of the well-known
+// operations sentinel errors.
+func operationError(err error, fallback coderr.CodeError) coderr.CodeError {
+	switch {
+	case errors.Is(err, operations.ErrOperationNotFound):
+		return ErrOperationNotFound
+	case errors.Is(err, operations.ErrOperationAlreadyFinished):
+		return ErrOperationAlreadyFinished
+	default:
+		return fallback
+	}
+}
+
+// problemTypeSlugs maps a coderr.CodeError to the stable URI slug used as
+// its RFC 7807 `type`. Errors without an entry fall back to a slug derived
+// from their numeric code.
+var problemTypeSlugs = map[coderr.CodeError]string{
+	ErrParseRequest:             "invalid-request",
+	ErrGetCluster:               "get-cluster-failed",
+	ErrCreateProcedure:          "create-procedure-failed",
+	ErrSubmitProcedure:          "submit-procedure-failed",
+	ErrForwardToLeader:          "forward-to-leader-failed",
+	ErrRouteTable:               "route-table-failed",
+	ErrDropTable:                "drop-table-failed",
+	ErrAllocShardID:             "alloc-shard-id-failed",
+	ErrGetOperation:             "get-operation-failed",
+	ErrOperationNotFound:        "operation-not-found",
+	ErrOperationAlreadyFinished: "operation-already-finished",
+	ErrCancelOperation:          "cancel-operation-failed",
+	ErrStreamUnsupported:        "stream-unsupported",
+	ErrGetShard:                 "shard-not-found",
+	cluster.ErrClusterNotFound:  "cluster-not-found",
+	cluster.ErrSchemaNotFound:   "schema-not-found",
+}
+
+// errorType derives the stable `https://ceresdb.io/errors/<slug>` URI RFC
+// 7807 uses as the problem `type` for apiErr.
+func errorType(apiErr coderr.CodeError) string {
+	if slug, ok := problemTypeSlugs[apiErr]; ok {
+		return fmt.Sprintf("https://ceresdb.io/errors/%s", slug)
+	}
+	return fmt.Sprintf("https://ceresdb.io/errors/code-%d", apiErr.Code())
+}
+
+// problemDetails is an RFC 7807 application/problem+json body.
+type problemDetails struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Code       int
+	RequestID  string
+	Extensions map[string]interface{}
+}
+
+// MarshalJSON flattens Extensions alongside the standard RFC 7807 members,
+// so structured context (cluster name, shard ID, ...) appears as top-level
+// fields rather than nested under a generic "extensions" key.
+func (p problemDetails) MarshalJSON() ([]byte, error) {
+	merged := make(map[string]interface{}, len(p.Extensions)+7)
+	for k, v := range p.Extensions {
+		merged[k] = v
+	}
+	merged["type"] = p.Type
+	merged["title"] = p.Title
+	merged["status"] = p.Status
+	merged["detail"] = p.Detail
+	merged["instance"] = p.Instance
+	merged["code"] = p.Code
+	merged["requestId"] = p.RequestID
+
+	return json.Marshal(merged)
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/CeresDB/ceresmeta/pkg/coderr"
+	"github.com/CeresDB/ceresmeta/server/coordinator/operations"
+)
+
+func TestProblemDetailsMarshalFlattensExtensions(t *testing.T) {
+	problem := problemDetails{
+		Type:      "https://ceresdb.io/errors/cluster-not-found",
+		Title:     "get cluster",
+		Status:    404,
+		Detail:    "get cluster failed, clusterName:test",
+		Instance:  "/api/v1/clusters/test",
+		Code:      int(ErrGetCluster.Code()),
+		RequestID: "req-1",
+		Extensions: map[string]interface{}{
+			"clusterName": "test",
+		},
+	}
+
+	b, err := json.Marshal(problem)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if decoded["clusterName"] != "test" {
+		t.Fatalf("clusterName = %v, want it flattened to the top level", decoded["clusterName"])
+	}
+	if _, ok := decoded["extensions"]; ok {
+		t.Fatal("Extensions should be flattened, not nested under an \"extensions\" key")
+	}
+	if decoded["requestId"] != "req-1" {
+		t.Fatalf("requestId = %v, want %q", decoded["requestId"], "req-1")
+	}
+}
+
+func TestErrorTypeFallsBackToCode(t *testing.T) {
+	unmapped := coderr.NewCodeError(coderr.Internal, "totally unmapped error for test")
+	got := errorType(unmapped)
+	want := fmt.Sprintf("https://ceresdb.io/errors/code-%d", unmapped.Code())
+	if got != want {
+		t.Fatalf("errorType = %q, want %q", got, want)
+	}
+}
+
+func TestOperationErrorMapsSentinelErrors(t *testing.T) {
+	if got := operationError(operations.ErrOperationNotFound, ErrGetOperation); got != ErrOperationNotFound {
+		t.Fatalf("operationError(ErrOperationNotFound) = %v, want ErrOperationNotFound", got)
+	}
+	if got := operationError(operations.ErrOperationAlreadyFinished, ErrGetOperation); got != ErrOperationAlreadyFinished {
+		t.Fatalf("operationError(ErrOperationAlreadyFinished) = %v, want ErrOperationAlreadyFinished", got)
+	}
+	other := errors.New("boom")
+	if got := operationError(other, ErrCancelOperation); got != ErrCancelOperation {
+		t.Fatalf("operationError(other) = %v, want the fallback ErrCancelOperation", got)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CeresDB/ceresmeta/pkg/log"
+	"github.com/CeresDB/ceresmeta/server/cluster"
+	"github.com/CeresDB/ceresmeta/server/coordinator/procedure"
+	"go.uber.org/zap"
+)
+
+// clientBacklog bounds how many unconsumed events a slow SSE client may
+// accumulate before it is dropped.
+const clientBacklog = 64
+
+// Event is a single topology or procedure change pushed to SSE subscribers.
+type Event struct {
+	Type    string      `json:"type"`
+	Time    time.Time   `json:"time"`
+	Payload interface{} `json:"payload"`
+}
+
+type eventsClient struct {
+	// types is nil when the client subscribed to every event type, rather
+	// than filtering to a specific set.
+	types map[string]struct{}
+	ch    chan Event
+}
+
+// subscribed reports whether the client wants events of the given type.
+func (c *eventsClient) subscribed(eventType string) bool {
+	if c.types == nil {
+		return true
+	}
+	_, ok := c.types[eventType]
+	return ok
+}
+
+// eventsBus fans out procedure and cluster topology changes to connected
+// HTTP clients, giving dashboards and external controllers a push-based
+// alternative to polling the operations API.
+type eventsBus struct {
+	lock    sync.RWMutex
+	clients map[*eventsClient]struct{}
+}
+
+// newEventsBus creates an eventsBus subscribed to procedureManager state
+// callbacks and clusterManager topology change notifications.
+func newEventsBus(procedureManager procedure.Manager, clusterManager cluster.Manager) *eventsBus {
+	bus := &eventsBus{
+		clients: make(map[*eventsClient]struct{}),
+	}
+
+	go bus.watchProcedures(procedureManager)
+	go bus.watchTopology(clusterManager)
+
+	return bus
+}
+
+func (b *eventsBus) watchProcedures(procedureManager procedure.Manager) {
+	for event := range procedureManager.Events() {
+		b.publish(Event{
+			Type:    "procedure",
+			Time:    time.Now(),
+			Payload: event,
+		})
+	}
+}
+
+func (b *eventsBus) watchTopology(clusterManager cluster.Manager) {
+	changes := clusterManager.TopologyChanges()
+	for change := range changes {
+		b.publish(Event{
+			Type:    change.EventType(),
+			Time:    time.Now(),
+			Payload: change,
+		})
+	}
+}
+
+func (b *eventsBus) publish(event Event) {
+	b.lock.RLock()
+	var slow []*eventsClient
+	for client := range b.clients {
+		if !client.subscribed(event.Type) {
+			continue
+		}
+		select {
+		case client.ch <- event:
+		default:
+			slow = append(slow, client)
+		}
+	}
+	b.lock.RUnlock()
+
+	for _, client := range slow {
+		log.Warn("disconnecting slow SSE client", zap.String("eventType", event.Type))
+		b.unsubscribe(client)
+	}
+}
+
+// subscribe registers a new client, filtered to types, or to every event
+// type when types is empty.
+func (b *eventsBus) subscribe(types []string) *eventsClient {
+	client := &eventsClient{
+		ch: make(chan Event, clientBacklog),
+	}
+	if len(types) > 0 {
+		client.types = make(map[string]struct{}, len(types))
+		for _, t := range types {
+			client.types[t] = struct{}{}
+		}
+	}
+
+	b.lock.Lock()
+	b.clients[client] = struct{}{}
+	b.lock.Unlock()
+
+	return client
+}
+
+// unsubscribe removes client and closes its channel. It is safe to call more
+// than once for the same client (e.g. once from publish(), evicting a slow
+// consumer, and once more from the handler's deferred cleanup).
+func (b *eventsBus) unsubscribe(client *eventsClient) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if _, ok := b.clients[client]; !ok {
+		return
+	}
+	delete(b.clients, client)
+	close(client.ch)
+}
+
+// events handles GET /events?types=procedure,shard,node, upgrading the
+// connection to a text/event-stream and pushing JSON events as they occur.
+func (a *API) events(writer http.ResponseWriter, req *http.Request) {
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		a.respondError(writer, req, ErrStreamUnsupported, "streaming unsupported")
+		return
+	}
+
+	// types is nil when the query param is absent/empty, which subscribe
+	// treats as "every event type" rather than the literal type "".
+	var types []string
+	if raw := req.URL.Query().Get("types"); raw != "" {
+		types = strings.Split(raw, ",")
+	}
+	client := a.eventsBus.subscribe(types)
+	defer a.eventsBus.unsubscribe(client)
+
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.Header().Set("Connection", "keep-alive")
+	writer.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case event, ok := <-client.ch:
+			if !ok {
+				// The bus dropped us, most likely for being too slow; tell the
+				// client to back off briefly before reconnecting.
+				fmt.Fprint(writer, "retry: 5000\n\n")
+				flusher.Flush()
+				return
+			}
+			b, err := json.Marshal(event)
+			if err != nil {
+				log.Error("marshal event failed", zap.Error(err))
+				continue
+			}
+			fmt.Fprintf(writer, "event: %s\ndata: %s\n\n", event.Type, b)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+package http
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestBus() *eventsBus {
+	return &eventsBus{clients: make(map[*eventsClient]struct{})}
+}
+
+func TestEventsBusFiltersByType(t *testing.T) {
+	bus := newTestBus()
+	client := bus.subscribe([]string{"shard"})
+	defer bus.unsubscribe(client)
+
+	bus.publish(Event{Type: "node"})
+	select {
+	case <-client.ch:
+		t.Fatal("received event of a type the client did not subscribe to")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	bus.publish(Event{Type: "shard"})
+	select {
+	case event := <-client.ch:
+		if event.Type != "shard" {
+			t.Fatalf("Type = %q, want %q", event.Type, "shard")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("did not receive subscribed event type")
+	}
+}
+
+func TestEventsBusEmptyTypesSubscribesToEverything(t *testing.T) {
+	bus := newTestBus()
+	client := bus.subscribe(nil)
+	defer bus.unsubscribe(client)
+
+	bus.publish(Event{Type: "anything"})
+	select {
+	case event := <-client.ch:
+		if event.Type != "anything" {
+			t.Fatalf("Type = %q, want %q", event.Type, "anything")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("client subscribed to no types did not receive an event")
+	}
+}
+
+func TestEventsBusDisconnectsSlowConsumer(t *testing.T) {
+	bus := newTestBus()
+	client := bus.subscribe(nil)
+
+	// Fill the client's backlog, then publish one more event: the client
+	// should be evicted (its channel closed) instead of silently losing
+	// events forever.
+	for i := 0; i < clientBacklog; i++ {
+		bus.publish(Event{Type: "anything"})
+	}
+	bus.publish(Event{Type: "anything"})
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		bus.lock.RLock()
+		_, stillSubscribed := bus.clients[client]
+		bus.lock.RUnlock()
+		if !stillSubscribed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("slow client was never disconnected")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// client.ch should be closed, though it may still hold buffered events
+	// that were queued before eviction.
+	for {
+		if _, ok := <-client.ch; !ok {
+			break
+		}
+	}
+}
+
+func TestEventsBusUnsubscribeIsIdempotent(t *testing.T) {
+	bus := newTestBus()
+	client := bus.subscribe(nil)
+
+	bus.unsubscribe(client)
+	bus.unsubscribe(client) // must not panic on double-close
+}
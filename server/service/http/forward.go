@@ -0,0 +1,114 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/CeresDB/ceresmeta/pkg/log"
+	"go.uber.org/zap"
+)
+
+// forwardedForHeader records the node that forwarded a request to the
+// current leader, so operators can detect forwarding loops.
+const forwardedForHeader = "X-Ceresmeta-Forwarded-For"
+
+// ForwardClient knows whether the local node is the Raft leader and, if not,
+// how to proxy a request to whichever node is.
+type ForwardClient struct {
+	localNodeName string
+	// getLeaderAddr resolves the current leader's HTTP address, e.g. from the
+	// embedded Raft/etcd cluster.
+	getLeaderAddr func() (string, error)
+	isLeader      func() bool
+
+	client *http.Client
+}
+
+// NewForwardClient creates a ForwardClient for localNodeName.
+func NewForwardClient(localNodeName string, isLeader func() bool, getLeaderAddr func() (string, error)) *ForwardClient {
+	return &ForwardClient{
+		localNodeName: localNodeName,
+		getLeaderAddr: getLeaderAddr,
+		isLeader:      isLeader,
+		client:        http.DefaultClient,
+	}
+}
+
+// forwardToLeader proxies req to the current leader. It returns isLeader
+// true (and a nil response) when the local node is itself the leader, so the
+// caller can continue handling the request locally.
+func (f *ForwardClient) forwardToLeader(req *http.Request) (*http.Response, bool, error) {
+	if f.isLeader() {
+		return nil, true, nil
+	}
+
+	leaderAddr, err := f.getLeaderAddr()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var body io.Reader
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, false, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(b))
+		body = bytes.NewReader(b)
+	}
+
+	forwardURL := *req.URL
+	forwardURL.Scheme = "http"
+	forwardURL.Host = leaderAddr
+
+	forwardReq, err := http.NewRequestWithContext(req.Context(), req.Method, forwardURL.String(), body)
+	if err != nil {
+		return nil, false, err
+	}
+	forwardReq.Header = req.Header.Clone()
+	forwardReq.Header.Set(forwardedForHeader, f.localNodeName)
+
+	resp, err := f.client.Do(forwardReq)
+	if err != nil {
+		return nil, false, err
+	}
+	return resp, false, nil
+}
+
+// instrumentation wraps handler so that, when the local node is not the
+// leader, the request is transparently proxied to the current leader
+// instead of being served locally.
+func (f *ForwardClient) instrumentation(handlerName string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, req *http.Request) {
+		// A forwardedForHeader present while this node is not the leader means
+		// the request already bounced off one node's (possibly stale) view of
+		// the leader; forwarding it again could loop forever (e.g. a node whose
+		// own getLeaderAddr resolves back to itself during an election). Only
+		// the actual leader may serve a request carrying this header.
+		if !f.isLeader() {
+			if forwardedFor := req.Header.Get(forwardedForHeader); forwardedFor != "" {
+				err := fmt.Errorf("forwarding loop detected: request already forwarded by %q, but local node %q is not the leader", forwardedFor, f.localNodeName)
+				log.Error("refusing to re-forward an already-forwarded request", zap.String("handlerName", handlerName), zap.String("forwardedFor", forwardedFor))
+				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		resp, isLeader, err := f.forwardToLeader(req)
+		if err != nil {
+			log.Error("forward to leader failed", zap.String("handlerName", handlerName), zap.Error(err))
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if isLeader {
+			handler.ServeHTTP(writer, req)
+			return
+		}
+		defer resp.Body.Close()
+		respondForward(writer, resp)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestForwardClientServesLocallyWhenLeader(t *testing.T) {
+	f := NewForwardClient("node-1", func() bool { return true }, func() (string, error) {
+		t.Fatal("getLeaderAddr should not be called when local node is the leader")
+		return "", nil
+	})
+
+	called := false
+	handler := f.instrumentation("test", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	handler(httptest.NewRecorder(), req)
+
+	if !called {
+		t.Fatal("handler was not invoked locally on the leader")
+	}
+}
+
+func TestForwardClientRejectsAlreadyForwardedRequestFromAnotherNode(t *testing.T) {
+	f := NewForwardClient("node-1", func() bool { return false }, func() (string, error) {
+		t.Fatal("getLeaderAddr should not be called once a forwarding loop is detected")
+		return "", nil
+	})
+
+	called := false
+	handler := f.instrumentation("test", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(forwardedForHeader, "node-2")
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	if called {
+		t.Fatal("a non-leader must not serve an already-forwarded request locally")
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestForwardClientRejectsSelfReferencingForward(t *testing.T) {
+	// Simulates a node whose own (possibly stale) getLeaderAddr resolves back
+	// to itself while isLeader() is false, e.g. mid-election: the header will
+	// equal the local node's own name, which must not be treated as safe to
+	// serve locally.
+	f := NewForwardClient("node-1", func() bool { return false }, func() (string, error) {
+		t.Fatal("getLeaderAddr should not be called once a forwarding loop is detected")
+		return "", nil
+	})
+
+	called := false
+	handler := f.instrumentation("test", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(forwardedForHeader, "node-1")
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	if called {
+		t.Fatal("a non-leader must not serve a self-referencing forwarded request locally")
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestForwardClientLeaderServesDespiteForwardedHeader(t *testing.T) {
+	f := NewForwardClient("node-1", func() bool { return true }, func() (string, error) {
+		t.Fatal("getLeaderAddr should not be called when local node is the leader")
+		return "", nil
+	})
+
+	called := false
+	handler := f.instrumentation("test", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(forwardedForHeader, "node-2")
+	handler(httptest.NewRecorder(), req)
+
+	if !called {
+		t.Fatal("the actual leader must serve the request regardless of forwardedForHeader")
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ceresmeta_http_requests_total",
+		Help: "Total number of HTTP requests handled by ceresmeta, partitioned by handler, method and status.",
+	}, []string{"handler", "method", "status"})
+
+	httpRequestDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ceresmeta_http_request_duration_seconds",
+		Help:    "Latency of HTTP requests handled by ceresmeta, partitioned by handler.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"handler"})
+
+	httpInFlightRequests = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ceresmeta_http_in_flight_requests",
+		Help: "Number of HTTP requests currently being served by ceresmeta, partitioned by handler.",
+	}, []string{"handler"})
+)
+
+func init() {
+	prometheus.MustRegister(httpRequestsTotal, httpRequestDurationSeconds, httpInFlightRequests)
+}
+
+// statusRecorder wraps http.ResponseWriter to capture the status code
+// written by the handler, since http.ResponseWriter does not expose it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush implements http.Flusher by delegating to the underlying
+// ResponseWriter, so wrapping in statusRecorder doesn't break streaming
+// handlers (e.g. the SSE endpoint) that need to flush per-event.
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// withMetrics records per-handler request count, latency, and in-flight
+// gauges for Prometheus scraping at /metrics.
+func withMetrics(handlerName string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		httpInFlightRequests.WithLabelValues(handlerName).Inc()
+		defer httpInFlightRequests.WithLabelValues(handlerName).Dec()
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: writer, status: http.StatusOK}
+		handler.ServeHTTP(recorder, request)
+
+		httpRequestDurationSeconds.WithLabelValues(handlerName).Observe(time.Since(start).Seconds())
+		httpRequestsTotal.WithLabelValues(handlerName, request.Method, strconv.Itoa(recorder.status)).Inc()
+	}
+}
+
+// WithMetrics makes every route registered afterwards record Prometheus
+// request count, latency, and in-flight metrics, composing with
+// WithInstrumentation and WithLeaderForwarding.
+func (r *Router) WithMetrics() *Router {
+	return r.WithInstrumentation(withMetrics)
+}
+
+// metricsHandler serves /metrics on the same router as the rest of the API.
+func metricsHandler() http.HandlerFunc {
+	return promhttp.Handler().ServeHTTP
+}
@@ -0,0 +1,32 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+package http
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// requestIDHeader is echoed on both success and error responses so a caller
+// can correlate a request across logs, traces, and support tickets.
+const requestIDHeader = "X-Request-Id"
+
+type requestIDKeyType struct{}
+
+var requestIDKey requestIDKeyType
+
+func withRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// requestIDFromContext returns the request ID attached by printRequestInsmt,
+// or "" if none is present (e.g. in tests that call a handler directly).
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+func newRequestID() string {
+	return uuid.New().String()
+}
@@ -0,0 +1,106 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// instrumentation wraps a named handler, e.g. for logging, metrics, or
+// leader forwarding.
+type instrumentation func(handlerName string, handler http.HandlerFunc) http.HandlerFunc
+
+// Router is a thin wrapper around mux.Router that lets handlers be
+// registered with a chain of instrumentation decorators (logging, metrics,
+// leader forwarding, ...) applied uniformly.
+type Router struct {
+	mux        *mux.Router
+	prefix     string
+	instms     []instrumentation
+	forwarding instrumentation
+}
+
+// routeOptions customizes how a single route is registered.
+type routeOptions struct {
+	skipForwarding bool
+}
+
+// RouteOption customizes the registration of a single route.
+type RouteOption func(*routeOptions)
+
+// NoForward opts a route out of leader forwarding, for read-only endpoints
+// that are safe to serve from a follower.
+func NoForward() RouteOption {
+	return func(o *routeOptions) { o.skipForwarding = true }
+}
+
+// New creates an empty Router.
+func New() *Router {
+	return &Router{
+		mux: mux.NewRouter(),
+	}
+}
+
+// WithPrefix sets a path prefix applied to every route registered afterwards.
+func (r *Router) WithPrefix(prefix string) *Router {
+	r.prefix = prefix
+	return r
+}
+
+// WithInstrumentation appends a decorator applied, in order, to every
+// handler registered afterwards. Decorators compose, so
+// WithInstrumentation(a).WithInstrumentation(b) wraps a handler as
+// b(a(handler)).
+func (r *Router) WithInstrumentation(instm instrumentation) *Router {
+	r.instms = append(r.instms, instm)
+	return r
+}
+
+// WithLeaderForwarding makes every route registered afterwards (unless
+// registered with NoForward) transparently proxy to the current Raft leader
+// when handled by a follower node, composing with WithInstrumentation.
+func (r *Router) WithLeaderForwarding(forwardClient *ForwardClient) *Router {
+	r.forwarding = forwardClient.instrumentation
+	return r
+}
+
+func (r *Router) handle(method, path string, handler http.HandlerFunc, opts ...RouteOption) {
+	options := routeOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	full := r.prefix + path
+	wrapped := handler
+	if r.forwarding != nil && !options.skipForwarding {
+		wrapped = r.forwarding(full, wrapped)
+	}
+	for _, instm := range r.instms {
+		wrapped = instm(full, wrapped)
+	}
+	r.mux.HandleFunc(full, wrapped).Methods(method)
+}
+
+func (r *Router) Get(path string, handler http.HandlerFunc, opts ...RouteOption) {
+	r.handle(http.MethodGet, path, handler, opts...)
+}
+
+func (r *Router) Post(path string, handler http.HandlerFunc, opts ...RouteOption) {
+	r.handle(http.MethodPost, path, handler, opts...)
+}
+
+func (r *Router) Delete(path string, handler http.HandlerFunc, opts ...RouteOption) {
+	r.handle(http.MethodDelete, path, handler, opts...)
+}
+
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mux.ServeHTTP(w, req)
+}
+
+// pathParam returns the value of a named path variable, e.g. the "{id}" in
+// a route registered as "/operations/{id}".
+func pathParam(req *http.Request, name string) string {
+	return mux.Vars(req)[name]
+}
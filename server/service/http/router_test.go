@@ -0,0 +1,62 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestRouterInstrumentationOrder verifies that decorators compose in
+// registration order: WithInstrumentation(a).WithInstrumentation(b) wraps a
+// handler as b(a(handler)), so a runs closest to the handler.
+func TestRouterInstrumentationOrder(t *testing.T) {
+	var order []string
+
+	track := func(name string) instrumentation {
+		return func(_ string, handler http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				handler.ServeHTTP(w, r)
+			}
+		}
+	}
+
+	r := New()
+	r.WithInstrumentation(track("a")).WithInstrumentation(track("b"))
+	r.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	want := []string{"b", "a", "handler"}
+	if len(order) != len(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
+
+// TestRouterMetricsPreservesFlusher verifies that WithMetrics' statusRecorder
+// wrapping doesn't break a streaming handler that type-asserts to
+// http.Flusher, as the SSE endpoint does.
+func TestRouterMetricsPreservesFlusher(t *testing.T) {
+	r := New()
+	r.WithMetrics()
+	r.Get("/stream", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(http.Flusher); !ok {
+			t.Error("ResponseWriter wrapped by WithMetrics does not implement http.Flusher")
+		}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+}
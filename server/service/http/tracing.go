@@ -0,0 +1,42 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+package http
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer is shared across every handler so procedure execution steps
+// started from procedureManager.Submit show up as child spans of the
+// request that triggered them.
+var tracer = otel.Tracer("github.com/CeresDB/ceresmeta/server/service/http")
+
+// withTracing continues a distributed trace from an incoming traceparent
+// header (e.g. from a ceresdb query node) and starts a span for the
+// handler, propagated into the request context so that
+// procedureManager.Submit can attach child spans for procedure steps.
+func withTracing(handlerName string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+
+		ctx, span := tracer.Start(ctx, handlerName, trace.WithAttributes(
+			attribute.String("http.method", request.Method),
+			attribute.String("http.target", request.URL.Path),
+		))
+		defer span.End()
+
+		handler.ServeHTTP(writer, request.WithContext(ctx))
+	}
+}
+
+// WithTracing makes every route registered afterwards start a span per
+// request and accept `traceparent` headers for distributed trace
+// continuation, composing with WithInstrumentation and WithMetrics.
+func (r *Router) WithTracing() *Router {
+	return r.WithInstrumentation(withTracing)
+}
@@ -0,0 +1,20 @@
+// Copyright 2022 CeresDB Project Authors. Licensed under Apache-2.0.
+
+// Package storage holds the etcd-backed persistence types shared by the
+// cluster, procedure, and operations subsystems.
+package storage
+
+import "context"
+
+// ShardID uniquely identifies a shard within a cluster.
+type ShardID uint64
+
+// KV is the minimal etcd-backed key-value interface that higher-level
+// stores (cluster metadata, procedure state, operations) build on.
+type KV interface {
+	Put(ctx context.Context, key string, value []byte) error
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// List returns the values of every key under prefix.
+	List(ctx context.Context, prefix string) (values [][]byte, err error)
+	Delete(ctx context.Context, key string) error
+}